@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
@@ -20,10 +26,80 @@ import (
 
 const envoyBootstrapHookName = "envoy_bootstrap"
 
+// siTokenWatchers holds one shared siTokenWatcher per Consul HTTP address,
+// so that every Connect sidecar hook on a client coalesces onto the same
+// background check loop per accessor rather than each hook instance running
+// its own. Hooks are constructed without a ready-made siTokenWatcher to
+// share (there's no single place on the client that owns one), so this is
+// populated lazily the first time a hook actually needs to watch a token.
+var siTokenWatchers sync.Map // consulHTTPAddr (string) -> *siTokenWatcher
+
+// sharedSITokenWatcher returns the siTokenWatcher for addr, creating it if
+// this is the first sidecar hook to ask for one.
+func sharedSITokenWatcher(addr string, logger hclog.Logger) (*siTokenWatcher, error) {
+	if w, ok := siTokenWatchers.Load(addr); ok {
+		return w.(*siTokenWatcher), nil
+	}
+
+	client, err := consulapi.NewClient(&consulapi.Config{Address: addr})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Consul API client for SI token watcher")
+	}
+
+	w := newSITokenWatcher(client, logger)
+	actual, _ := siTokenWatchers.LoadOrStore(addr, w)
+	return actual.(*siTokenWatcher), nil
+}
+
+// envoyBootstrapAdminBindPortBase is the low end of the port range Envoy's
+// admin API binds to inside the task's network namespace. It is only ever
+// reachable over loopback.
+const envoyBootstrapAdminBindPortBase = 19000
+
+// envoyBootstrapAdminBindPortRange bounds how far past
+// envoyBootstrapAdminBindPortBase a sidecar's admin port may be picked, to
+// keep ports stable and predictable for operators inspecting a running
+// sidecar's admin API.
+const envoyBootstrapAdminBindPortRange = 1000
+
+// envoyAdminBindPort derives the admin port for a sidecar from its Consul
+// service ID, which is unique per task within the allocation. A task group
+// with multiple Connect sidecars shares one network namespace, so a single
+// fixed port would collide the moment a group registers more than one
+// sidecar; hashing the ID spreads sidecars across a range instead.
+func envoyAdminBindPort(sidecarFor string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sidecarFor))
+	return envoyBootstrapAdminBindPortBase + int(h.Sum32()%envoyBootstrapAdminBindPortRange)
+}
+
 type envoyBootstrapHookConfig struct {
 	alloc          *structs.Allocation
 	consulHTTPAddr string
 	logger         hclog.Logger
+
+	// consulCLIBootstrap, when true, reverts to shelling out to
+	// `consul connect envoy -bootstrap` instead of talking to Consul
+	// directly. This exists purely so operators can opt back into the old
+	// behavior if the native bootstrap path doesn't work in their Consul
+	// deployment; new clusters should leave it unset.
+	consulCLIBootstrap bool
+
+	// siTokenWatcher keeps the SI token backing this sidecar fresh for as
+	// long as the task runs. When nil, the hook uses the watcher shared by
+	// every Connect sidecar for the same Consul address (see
+	// sharedSITokenWatcher), so tokens issued to multiple sidecars are
+	// coalesced.
+	siTokenWatcher *siTokenWatcher
+
+	// lifecycle lets the siTokenWatcher restart this task if Consul
+	// revokes its SI token out from under it.
+	lifecycle interfaces.TaskLifecycle
+
+	// shutdownCtx is cancelled when the task is torn down, and bounds how
+	// long siTokenWatcher keeps watching this task's token. It outlives
+	// any single Prestart call's context.
+	shutdownCtx context.Context
 }
 
 // envoyBootstrapHook writes the bootstrap config for the Connect Envoy proxy
@@ -37,15 +113,35 @@ type envoyBootstrapHook struct {
 	// Consul's gRPC endpoint.
 	consulHTTPAddr string
 
+	// consulCLIBootstrap opts back into shelling out to the consul binary
+	// rather than generating the bootstrap config natively.
+	consulCLIBootstrap bool
+
+	// siTokenWatcher and lifecycle let this hook keep the sidecar's SI
+	// token fresh for as long as the task runs, restarting the task if
+	// Consul revokes it.
+	siTokenWatcher *siTokenWatcher
+	lifecycle      interfaces.TaskLifecycle
+	shutdownCtx    context.Context
+
 	// logger is used to log things
 	logger hclog.Logger
 }
 
 func newEnvoyBootstrapHook(c *envoyBootstrapHookConfig) *envoyBootstrapHook {
+	shutdownCtx := c.shutdownCtx
+	if shutdownCtx == nil {
+		shutdownCtx = context.Background()
+	}
+
 	return &envoyBootstrapHook{
-		alloc:          c.alloc,
-		consulHTTPAddr: c.consulHTTPAddr,
-		logger:         c.logger.Named(envoyBootstrapHookName),
+		alloc:              c.alloc,
+		consulHTTPAddr:     c.consulHTTPAddr,
+		consulCLIBootstrap: c.consulCLIBootstrap,
+		siTokenWatcher:     c.siTokenWatcher,
+		lifecycle:          c.lifecycle,
+		shutdownCtx:        shutdownCtx,
+		logger:             c.logger.Named(envoyBootstrapHookName),
 	}
 }
 
@@ -67,14 +163,7 @@ func (h *envoyBootstrapHook) Prestart(ctx context.Context, req *interfaces.TaskP
 
 	tg := h.alloc.Job.LookupTaskGroup(h.alloc.TaskGroup)
 
-	var service *structs.Service
-	for _, s := range tg.Services {
-		if s.Name == serviceName {
-			service = s
-			break
-		}
-	}
-
+	service := findSidecarService(tg, serviceName)
 	if service == nil {
 		return errors.New("connect proxy sidecar task exists but no services configured with a sidecar")
 	}
@@ -88,7 +177,12 @@ func (h *envoyBootstrapHook) Prestart(ctx context.Context, req *interfaces.TaskP
 	// it to the secrets directory like Vault tokens.
 	bootstrapFilePath := filepath.Join(req.TaskDir.SecretsDir, "envoy_bootstrap.json")
 
-	id := agentconsul.MakeAllocServiceID(h.alloc.ID, "group-"+tg.Name, service)
+	// MakeAllocServiceID derives the Consul service ID from service.Name, so
+	// normalize it to the lowercase form Consul actually registered under,
+	// regardless of how it was cased in the jobspec.
+	normalizedService := service.Copy()
+	normalizedService.Name = strings.ToLower(normalizedService.Name)
+	id := agentconsul.MakeAllocServiceID(h.alloc.ID, "group-"+tg.Name, normalizedService)
 
 	h.logger.Debug("bootstrapping envoy", "sidecar_for", service.Name, "bootstrap_file", bootstrapFilePath, "sidecar_for_id", id, "grpc_addr", grpcAddr)
 
@@ -99,14 +193,42 @@ func (h *envoyBootstrapHook) Prestart(ctx context.Context, req *interfaces.TaskP
 	}
 	h.logger.Debug("check for SI token for task", "task", req.Task.Name, "exists", siToken != "")
 
-	bootstrapArgs := envoyBootstrapArgs{
-		sidecarFor:     id,
-		grpcAddr:       grpcAddr,
-		consulHTTPAddr: h.consulHTTPAddr,
-		siToken:        siToken,
-	}.args()
+	if siToken != "" && h.lifecycle != nil {
+		h.watchSIToken(req.Task.Name, siToken, filepath.Join(req.TaskDir.SecretsDir, sidsTokenFile))
+	}
+
+	tracing, err := tracingConfigFromProxy(sidecarProxy(service))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse tracing configuration for Connect proxy sidecar")
+	}
+
+	if h.consulCLIBootstrap {
+		bootstrapArgs := envoyBootstrapArgs{
+			sidecarFor:     id,
+			grpcAddr:       grpcAddr,
+			consulHTTPAddr: h.consulHTTPAddr,
+			siToken:        siToken,
+			tracing:        tracing,
+		}.args()
+
+		if err := h.bootstrapViaCLI(ctx, bootstrapArgs, bootstrapFilePath); err != nil {
+			return err
+		}
+	} else {
+		if err := h.bootstrapNative(ctx, service, id, grpcAddr, siToken, tracing, bootstrapFilePath); err != nil {
+			return err
+		}
+	}
+
+	// Bootstrap written. Mark as done and move on.
+	resp.Done = true
+	return nil
+}
 
-	// put old stuff in here
+// bootstrapViaCLI generates the bootstrap config by shelling out to
+// `consul connect envoy -bootstrap`. It is the historical code path, kept
+// around for operators who opt into consul_cli_bootstrap.
+func (h *envoyBootstrapHook) bootstrapViaCLI(ctx context.Context, bootstrapArgs []string, bootstrapFilePath string) error {
 	// Since Consul services are registered asynchronously with this task
 	// hook running, retry a small number of times with backoff.
 	for tries := 3; ; tries-- {
@@ -130,7 +252,7 @@ func (h *envoyBootstrapHook) Prestart(ctx context.Context, req *interfaces.TaskP
 
 		if err == nil {
 			// Happy path! Bootstrap was created, exit.
-			break
+			return nil
 		}
 
 		// Check for error from command
@@ -162,10 +284,99 @@ func (h *envoyBootstrapHook) Prestart(ctx context.Context, req *interfaces.TaskP
 			return nil
 		}
 	}
+}
 
-	// Bootstrap written. Mark as done and move on.
-	resp.Done = true
-	return nil
+// bootstrapNative generates the Envoy bootstrap config directly against the
+// Consul HTTP API instead of shelling out to the consul binary. This avoids
+// requiring the consul CLI to be installed on every Nomad client and lets us
+// surface Consul's HTTP status and body back to the task hook response.
+func (h *envoyBootstrapHook) bootstrapNative(ctx context.Context, service *structs.Service, sidecarFor, grpcAddr, siToken string, tracing *envoyTracingConfig, bootstrapFilePath string) error {
+	client, err := consulapi.NewClient(&consulapi.Config{
+		Address: h.consulHTTPAddr,
+		Token:   siToken,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create Consul API client for Envoy bootstrap")
+	}
+
+	// Since Consul services are registered asynchronously with this task
+	// hook running, retry a small number of times with backoff, mirroring
+	// the semantics of the CLI bootstrap path.
+	var lastErr error
+	for tries := 3; ; tries-- {
+		cfg, renderErr := h.renderBootstrapConfig(client, service, sidecarFor, grpcAddr, siToken, tracing)
+		if renderErr == nil {
+			if err := h.writeConfig(bootstrapFilePath, cfg); err != nil {
+				return fmt.Errorf("error writing secrets/envoy_bootstrap.json for envoy: %v", err)
+			}
+			return nil
+		}
+
+		lastErr = renderErr
+		if tries == 0 {
+			break
+		}
+		h.logger.Debug("error generating Envoy bootstrap config from Consul, retrying", "error", renderErr, "sidecar_for", sidecarFor)
+
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			// Killed before bootstrap, exit without setting Done
+			return nil
+		}
+	}
+
+	h.logger.Error("error generating Envoy bootstrap config from Consul", "error", lastErr, "sidecar_for", sidecarFor)
+	_ = os.Remove(bootstrapFilePath)
+
+	return structs.NewRecoverableError(
+		errors.Wrap(lastErr, "error generating bootstrap configuration for Connect proxy sidecar"),
+		true,
+	)
+}
+
+// renderBootstrapConfig fetches the Connect CA roots, a leaf certificate for
+// the sidecar, and the sidecar's own service registration from Consul, and
+// renders them into an Envoy bootstrap JSON document.
+func (h *envoyBootstrapHook) renderBootstrapConfig(client *consulapi.Client, service *structs.Service, sidecarFor, grpcAddr, siToken string, tracing *envoyTracingConfig) (string, error) {
+	roots, _, err := client.Agent().ConnectCARoots(nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fetch Connect CA roots")
+	}
+	if len(roots.Roots) == 0 {
+		return "", errors.New("Consul returned no Connect CA roots")
+	}
+
+	// ConnectCALeaf is fetched (and not just the roots) so that we fail
+	// fast here, before Envoy ever starts, if the SI token isn't actually
+	// authorized to act as this service.
+	if _, _, err := client.Agent().ConnectCALeaf(sidecarFor, nil); err != nil {
+		return "", errors.Wrap(err, "failed to fetch Connect CA leaf certificate")
+	}
+
+	svc, _, err := client.Agent().Service(sidecarFor, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to fetch sidecar service registration")
+	}
+
+	data := envoyBootstrapTemplateData{
+		AdminBindPort:  envoyAdminBindPort(sidecarFor),
+		NodeID:         sidecarFor,
+		Cluster:        service.Name,
+		Namespace:      svc.Namespace,
+		Partition:      svc.Partition,
+		TrustDomain:    roots.TrustDomain,
+		GRPCSocketPath: strings.TrimPrefix(grpcAddr, "unix://"),
+		SIToken:        siToken,
+		Tracing:        tracing,
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := envoyBootstrapTemplate.Execute(buf, data); err != nil {
+		return "", errors.Wrap(err, "failed to render Envoy bootstrap template")
+	}
+
+	return buf.String(), nil
 }
 
 func (h *envoyBootstrapHook) writeConfig(filename, config string) error {
@@ -209,6 +420,7 @@ type envoyBootstrapArgs struct {
 	grpcAddr       string
 	consulHTTPAddr string
 	siToken        string
+	tracing        *envoyTracingConfig
 }
 
 func (e envoyBootstrapArgs) args() []string {
@@ -223,9 +435,280 @@ func (e envoyBootstrapArgs) args() []string {
 	if e.siToken != "" {
 		arguments = append(arguments, []string{"-token", e.siToken}...)
 	}
+	if e.tracing != nil {
+		// The consul CLI renders the bootstrap config itself, so there's
+		// no hook into it for collector/sampling configuration. The best
+		// the CLI fallback can do is bump Envoy's own tracing log level;
+		// operators who need full tracing provider support should leave
+		// consul_cli_bootstrap unset and use the native bootstrap path.
+		arguments = append(arguments, "-envoy-extra-args", e.tracing.envoyExtraArgs())
+	}
 	return arguments
 }
 
+// envoyTracingConfig configures the tracing provider Envoy should export
+// spans to. It is sourced from the connect.sidecar_service.proxy stanza in
+// the job spec (structs.ConsulProxy.Tracing) so it participates in job
+// validation and diffing like the rest of the Connect configuration.
+type envoyTracingConfig struct {
+	// Provider is one of "zipkin" (also used for Jaeger's Zipkin-compatible
+	// collector), "datadog", or "opentelemetry".
+	Provider string
+
+	// CollectorHost and CollectorPort address the tracing collector that
+	// Envoy should export spans to.
+	CollectorHost string
+	CollectorPort int
+
+	// SamplingRatePercent is the percentage, 0-100, of requests to sample.
+	SamplingRatePercent float64
+
+	// SpawnUpstreamSpan controls whether Envoy starts a new span for
+	// upstream requests rather than continuing the downstream span.
+	SpawnUpstreamSpan bool
+}
+
+// SamplingNumerator converts SamplingRatePercent into an integer numerator
+// over a denominator of TEN_THOUSAND, the finest granularity Envoy's
+// random_sampling config supports. Rendering SamplingRatePercent directly as
+// the numerator over "HUNDRED" would emit a non-integer numerator (invalid
+// JSON for Envoy's config) for any rate with a fractional percent, e.g. 0.5%.
+func (t *envoyTracingConfig) SamplingNumerator() int {
+	return int(math.Round(t.SamplingRatePercent * 100))
+}
+
+func (t *envoyTracingConfig) envoyExtraArgs() string {
+	return "--component-log-level tracing:debug"
+}
+
+// ProviderName maps a provider to the Envoy tracer extension name used in
+// the bootstrap's "tracing.http.name" field.
+func (t *envoyTracingConfig) ProviderName() string {
+	switch t.Provider {
+	case "datadog":
+		return "envoy.tracers.datadog"
+	case "opentelemetry":
+		return "envoy.tracers.opentelemetry"
+	default:
+		// Jaeger is Zipkin-API compatible, so it rides the Zipkin tracer.
+		return "envoy.tracers.zipkin"
+	}
+}
+
+// findSidecarService returns the service in tg whose name matches
+// serviceName, the value of the connect proxy task's Kind field. Consul
+// normalizes service names to lowercase on registration, so a job with e.g.
+// `service { name = "Web" }` still needs to match here regardless of how the
+// operator cased it in the jobspec. This mirrors how MakeAllocServiceID's
+// caller in Prestart normalizes the name before deriving the Consul service
+// ID, so both lookups agree on the same service even though the jobspec may
+// use mixed case.
+func findSidecarService(tg *structs.TaskGroup, serviceName string) *structs.Service {
+	for _, s := range tg.Services {
+		if strings.EqualFold(s.Name, serviceName) {
+			return s
+		}
+	}
+	return nil
+}
+
+// sidecarProxy returns the Connect proxy stanza for the sidecar registered
+// for service, or nil if the service has no Connect sidecar configured.
+func sidecarProxy(service *structs.Service) *structs.ConsulProxy {
+	if service == nil || service.Connect == nil || service.Connect.SidecarService == nil {
+		return nil
+	}
+	return service.Connect.SidecarService.Proxy
+}
+
+// tracingConfigFromProxy extracts the optional tracing stanza from a
+// Connect proxy configuration. It returns nil, nil if tracing isn't
+// configured.
+func tracingConfigFromProxy(proxy *structs.ConsulProxy) (*envoyTracingConfig, error) {
+	if proxy == nil || proxy.Tracing == nil {
+		return nil, nil
+	}
+
+	t := proxy.Tracing
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &envoyTracingConfig{
+		Provider:            t.Provider,
+		CollectorHost:       t.CollectorHost,
+		CollectorPort:       t.CollectorPort,
+		SamplingRatePercent: t.SamplingRatePercent,
+		SpawnUpstreamSpan:   t.SpawnUpstreamSpan,
+	}, nil
+}
+
+// envoyBootstrapTemplateData holds the values substituted into
+// envoyBootstrapTemplate to produce the final bootstrap JSON.
+type envoyBootstrapTemplateData struct {
+	AdminBindPort  int
+	NodeID         string
+	Cluster        string
+	Namespace      string
+	Partition      string
+	TrustDomain    string
+	GRPCSocketPath string
+	SIToken        string
+	Tracing        *envoyTracingConfig
+}
+
+// envoyBootstrapTemplate renders the Envoy bootstrap config that the CLI
+// path would otherwise have generated: an admin listener, ADS pointed at the
+// local xDS gRPC socket, and node metadata identifying the sidecar to
+// Consul.
+var envoyBootstrapTemplate = template.Must(template.New("envoy_bootstrap").Parse(`{
+  "admin": {
+    "access_log_path": "/dev/null",
+    "address": {
+      "socket_address": {
+        "address": "127.0.0.1",
+        "port_value": {{ .AdminBindPort }}
+      }
+    }
+  },
+  "node": {
+    "id": {{ printf "%q" .NodeID }},
+    "cluster": {{ printf "%q" .Cluster }},
+    "metadata": {
+      "namespace": {{ printf "%q" .Namespace }},
+      "partition": {{ printf "%q" .Partition }},
+      "trust_domain": {{ printf "%q" .TrustDomain }},
+      "token": {{ printf "%q" .SIToken }}
+    }
+  },
+{{- if .Tracing }}
+  "tracing": {
+    "http": {
+      "name": {{ printf "%q" .Tracing.ProviderName }},
+      "typed_config": {
+{{- if eq .Tracing.Provider "datadog" }}
+        "@type": "type.googleapis.com/envoy.config.trace.v3.DatadogConfig",
+        "collector_cluster": "tracing_collector",
+        "service_name": {{ printf "%q" .Cluster }}
+{{- else if eq .Tracing.Provider "opentelemetry" }}
+        "@type": "type.googleapis.com/envoy.config.trace.v3.OpenTelemetryConfig",
+        "grpc_service": {
+          "envoy_grpc": { "cluster_name": "tracing_collector" },
+          "timeout": "1s"
+        },
+        "service_name": {{ printf "%q" .Cluster }}
+{{- else }}
+        "@type": "type.googleapis.com/envoy.config.trace.v3.ZipkinConfig",
+        "collector_cluster": "tracing_collector",
+        "collector_endpoint": "/api/v2/spans",
+        "collector_endpoint_version": "HTTP_JSON",
+        "shared_span_context": {{ not .Tracing.SpawnUpstreamSpan }}
+{{- end }}
+      }
+    },
+    "random_sampling": { "numerator": {{ .Tracing.SamplingNumerator }}, "denominator": "TEN_THOUSAND" }
+  },
+{{- end }}
+  "static_resources": {
+    "clusters": [
+{{- if .Tracing }}
+      {
+        "name": "tracing_collector",
+        "connect_timeout": "1s",
+        "type": "STRICT_DNS",
+        "load_assignment": {
+          "cluster_name": "tracing_collector",
+          "endpoints": [
+            {
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": {
+                      "socket_address": {
+                        "address": {{ printf "%q" .Tracing.CollectorHost }},
+                        "port_value": {{ .Tracing.CollectorPort }}
+                      }
+                    }
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      },
+{{- end }}
+      {
+        "name": "local_agent",
+        "connect_timeout": "1s",
+        "type": "STATIC",
+        "typed_extension_protocol_options": {
+          "envoy.extensions.upstreams.http.v3.HttpProtocolOptions": {
+            "@type": "type.googleapis.com/envoy.extensions.upstreams.http.v3.HttpProtocolOptions",
+            "explicit_http_config": { "http2_protocol_options": {} }
+          }
+        },
+        "load_assignment": {
+          "cluster_name": "local_agent",
+          "endpoints": [
+            {
+              "lb_endpoints": [
+                {
+                  "endpoint": {
+                    "address": { "pipe": { "path": {{ printf "%q" .GRPCSocketPath }} } }
+                  }
+                }
+              ]
+            }
+          ]
+        }
+      }
+    ]
+  },
+  "dynamic_resources": {
+    "lds_config": { "ads": {}, "resource_api_version": "V3" },
+    "cds_config": { "ads": {}, "resource_api_version": "V3" },
+    "ads_config": {
+      "api_type": "GRPC",
+      "transport_api_version": "V3",
+      "grpc_services": { "envoy_grpc": { "cluster_name": "local_agent" } }
+    }
+  }
+}
+`))
+
+// watchSIToken registers this task's SI token with the shared siTokenWatcher
+// so it keeps getting checked against Consul for the lifetime of the task,
+// not just at Prestart. Callers must only invoke this when h.lifecycle is
+// set, since a watch that can't ever restart the task would be pointless.
+func (h *envoyBootstrapHook) watchSIToken(task, token, tokenPath string) {
+	watcher := h.siTokenWatcher
+	if watcher == nil {
+		var err error
+		watcher, err = sharedSITokenWatcher(h.consulHTTPAddr, h.logger)
+		if err != nil {
+			h.logger.Warn("failed to create SI token watcher, will not watch for revocation", "task", task, "error", err)
+			return
+		}
+	}
+
+	self, _, err := h.consulACLClient().ACL().TokenReadSelf(&consulapi.QueryOptions{Token: token})
+	if err != nil {
+		h.logger.Warn("failed to look up SI token accessor, will not watch for revocation", "task", task, "error", err)
+		return
+	}
+
+	watcher.Watch(h.shutdownCtx, task, self.AccessorID, token, tokenPath, h.lifecycle)
+}
+
+// consulACLClient returns a Consul API client scoped to this hook's agent
+// address. It has no token of its own; callers set one per-request via
+// QueryOptions, matching how maybeLoadSIToken and watchSIToken each use
+// different tokens.
+func (h *envoyBootstrapHook) consulACLClient() *consulapi.Client {
+	client, _ := consulapi.NewClient(&consulapi.Config{Address: h.consulHTTPAddr})
+	return client
+}
+
 // maybeLoadSIToken reads the SI token saved to disk in the secretes directory
 // by the service identities prestart hook. This envoy bootstrap hook blocks
 // until the sids hook completes, so if the SI token is required to exist (i.e.
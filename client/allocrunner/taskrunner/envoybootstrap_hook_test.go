@@ -0,0 +1,117 @@
+package taskrunner
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSidecarService_CaseInsensitive(t *testing.T) {
+	tg := &structs.TaskGroup{
+		Services: []*structs.Service{
+			{Name: "Web"},
+			{Name: "api"},
+		},
+	}
+
+	require.Equal(t, "Web", findSidecarService(tg, "web").Name)
+	require.Equal(t, "Web", findSidecarService(tg, "WEB").Name)
+	require.Equal(t, "api", findSidecarService(tg, "API").Name)
+	require.Nil(t, findSidecarService(tg, "missing"))
+}
+
+func TestEnvoyAdminBindPort_Stable(t *testing.T) {
+	port := envoyAdminBindPort("web-sidecar-proxy")
+	require.Equal(t, port, envoyAdminBindPort("web-sidecar-proxy"))
+	require.GreaterOrEqual(t, port, envoyBootstrapAdminBindPortBase)
+	require.Less(t, port, envoyBootstrapAdminBindPortBase+envoyBootstrapAdminBindPortRange)
+
+	// Different sidecars in the same group should, in the common case,
+	// land on different ports rather than all colliding on the base port.
+	require.NotEqual(t, port, envoyAdminBindPort("api-sidecar-proxy"))
+}
+
+func TestSharedSITokenWatcher_SingletonPerAddr(t *testing.T) {
+	logger := hclog.NewNullLogger()
+
+	a1, err := sharedSITokenWatcher("http://127.0.0.1:8500", logger)
+	require.NoError(t, err)
+	a2, err := sharedSITokenWatcher("http://127.0.0.1:8500", logger)
+	require.NoError(t, err)
+	require.Same(t, a1, a2, "same address should reuse the same watcher")
+
+	b, err := sharedSITokenWatcher("http://127.0.0.1:8501", logger)
+	require.NoError(t, err)
+	require.NotSame(t, a1, b, "different addresses should get distinct watchers")
+}
+
+func TestEnvoyBootstrapTemplate_TracingTypedConfigPerProvider(t *testing.T) {
+	cases := []struct {
+		provider     string
+		wantTypeURL  string
+		wantHasField string
+	}{
+		{provider: "zipkin", wantTypeURL: "type.googleapis.com/envoy.config.trace.v3.ZipkinConfig", wantHasField: "collector_endpoint"},
+		{provider: "datadog", wantTypeURL: "type.googleapis.com/envoy.config.trace.v3.DatadogConfig", wantHasField: "service_name"},
+		{provider: "opentelemetry", wantTypeURL: "type.googleapis.com/envoy.config.trace.v3.OpenTelemetryConfig", wantHasField: "grpc_service"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.provider, func(t *testing.T) {
+			data := envoyBootstrapTemplateData{
+				AdminBindPort: 19000,
+				NodeID:        "web-sidecar-proxy",
+				Cluster:       "web",
+				Tracing: &envoyTracingConfig{
+					Provider:            c.provider,
+					CollectorHost:       "collector.service.consul",
+					CollectorPort:       9411,
+					SamplingRatePercent: 100,
+				},
+			}
+
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, envoyBootstrapTemplate.Execute(buf, data))
+
+			var rendered map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &rendered), "rendered bootstrap config must be valid JSON")
+
+			tracing := rendered["tracing"].(map[string]interface{})
+			http := tracing["http"].(map[string]interface{})
+			typedConfig := http["typed_config"].(map[string]interface{})
+
+			require.Equal(t, c.wantTypeURL, typedConfig["@type"])
+			require.Contains(t, typedConfig, c.wantHasField)
+
+			// The tracer name Envoy dispatches on must agree with the
+			// typed_config's declared @type, or Envoy rejects the config.
+			wantName := map[string]string{
+				"zipkin":        "envoy.tracers.zipkin",
+				"datadog":       "envoy.tracers.datadog",
+				"opentelemetry": "envoy.tracers.opentelemetry",
+			}[c.provider]
+			require.Equal(t, wantName, http["name"])
+		})
+	}
+}
+
+func TestEnvoyTracingConfig_SamplingNumerator(t *testing.T) {
+	cases := []struct {
+		percent float64
+		want    int
+	}{
+		{percent: 100, want: 10000},
+		{percent: 50, want: 5000},
+		{percent: 0.5, want: 50},
+		{percent: 0, want: 0},
+	}
+
+	for _, c := range cases {
+		tracing := &envoyTracingConfig{SamplingRatePercent: c.percent}
+		require.Equal(t, c.want, tracing.SamplingNumerator())
+	}
+}
@@ -0,0 +1,298 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/nomad/client/allocrunner/interfaces"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// siTokenCheckInterval is how often a watched SI token is re-verified
+	// against Consul, analogous to a Vault LifetimeWatcher's renewal loop.
+	siTokenCheckInterval = 30 * time.Second
+
+	// siTokenMinBackoff and siTokenMaxBackoff bound the exponential
+	// backoff applied after a 5xx from Consul's ACL API, mirroring
+	// RenewBehaviorIgnoreErrors in the Vault lifetime watcher.
+	siTokenMinBackoff = 5 * time.Second
+	siTokenMaxBackoff = 2 * time.Minute
+)
+
+// siTokenWatchee is the subset of a task hook that the siTokenWatcher needs
+// in order to recover a revoked SI token, or re-subscribe it under a new
+// accessor if the sids hook rotates its token file.
+type siTokenWatchee struct {
+	task      string
+	tokenPath string
+	lifecycle interfaces.TaskLifecycle
+
+	// ctx is the context originally passed to Watch for this task. It's
+	// kept so a rotation can re-register the watch under the new
+	// accessor with the same lifetime the caller asked for.
+	ctx context.Context
+}
+
+// watchedToken tracks every sidecar currently relying on a given SI token
+// accessor, so that a single shared token only costs one Consul query per
+// check interval no matter how many sidecars were issued it.
+type watchedToken struct {
+	token   string
+	watches map[string]*siTokenWatchee // keyed by task name
+	cancel  context.CancelFunc
+}
+
+// siTokenWatcher keeps Consul Service Identity tokens used by long-lived
+// Connect sidecars fresh. Unlike Vault tokens, SI tokens aren't renewed in
+// the traditional sense -- they either remain valid or are revoked -- so
+// "keeping them fresh" here means periodically confirming the token Consul
+// issued is still accepted, noticing if the sids hook rotated the token file
+// out from under a running sidecar, and restarting the task if Consul has
+// revoked the token entirely.
+type siTokenWatcher struct {
+	logger hclog.Logger
+	client *consulapi.Client
+
+	lock sync.Mutex
+	// tokens is keyed by accessor ID.
+	tokens map[string]*watchedToken
+}
+
+func newSITokenWatcher(client *consulapi.Client, logger hclog.Logger) *siTokenWatcher {
+	return &siTokenWatcher{
+		logger: logger.Named("si_token_watcher"),
+		client: client,
+		tokens: make(map[string]*watchedToken),
+	}
+}
+
+// Watch registers task's SI token (identified by accessorID) to be
+// periodically checked for the lifetime of ctx. If another task is already
+// watching the same accessor, the two are coalesced onto the same
+// background check loop.
+func (w *siTokenWatcher) Watch(ctx context.Context, task, accessorID, token, tokenPath string, lifecycle interfaces.TaskLifecycle) {
+	if accessorID == "" || token == "" {
+		return
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	wt, ok := w.tokens[accessorID]
+	if !ok {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		wt = &watchedToken{
+			token:   token,
+			watches: make(map[string]*siTokenWatchee),
+			cancel:  cancel,
+		}
+		w.tokens[accessorID] = wt
+		go w.run(watchCtx, accessorID, wt)
+	}
+
+	wt.watches[task] = &siTokenWatchee{
+		task:      task,
+		tokenPath: tokenPath,
+		lifecycle: lifecycle,
+		ctx:       ctx,
+	}
+
+	// Stop watching on behalf of this task once its context ends, without
+	// tearing down the shared accessor loop if other tasks still need it.
+	go func() {
+		<-ctx.Done()
+		w.Unwatch(accessorID, task)
+	}()
+}
+
+// Unwatch removes task's interest in accessorID. Once the last task watching
+// an accessor unwatches, the background check loop for it is stopped.
+func (w *siTokenWatcher) Unwatch(accessorID, task string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	wt, ok := w.tokens[accessorID]
+	if !ok {
+		return
+	}
+
+	delete(wt.watches, task)
+	if len(wt.watches) == 0 {
+		wt.cancel()
+		delete(w.tokens, accessorID)
+	}
+}
+
+// run is the per-accessor background loop. It exits when ctx is cancelled,
+// which happens once every task sharing the accessor has unwatched it.
+func (w *siTokenWatcher) run(ctx context.Context, accessorID string, wt *watchedToken) {
+	backoff := siTokenMinBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(siTokenCheckInterval):
+		}
+
+		revoked, retryable, err := w.checkToken(ctx, wt.token)
+		switch {
+		case err != nil && retryable:
+			w.logger.Warn("error checking SI token, will retry", "accessor_id", accessorID, "error", err, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > siTokenMaxBackoff {
+				backoff = siTokenMaxBackoff
+			}
+			continue
+		case err != nil:
+			// Errors checkToken reports as not retryable aren't a sign the
+			// token is revoked; log and keep the existing backoff.
+			w.logger.Warn("error checking SI token", "accessor_id", accessorID, "error", err)
+			continue
+		}
+
+		backoff = siTokenMinBackoff
+
+		if !revoked {
+			w.reloadRotatedTokens(accessorID, wt)
+			continue
+		}
+
+		w.logger.Warn("SI token revoked, restarting affected tasks", "accessor_id", accessorID)
+		w.restartWatchees(wt)
+		return
+	}
+}
+
+// checkToken calls Consul's /v1/acl/token/self to determine whether token is
+// still valid. The second return value reports whether the error (if any)
+// is worth retrying: a 5xx from Consul, a dropped connection, a DNS blip, or
+// anything else that isn't a definitive "this token is gone" response.
+func (w *siTokenWatcher) checkToken(ctx context.Context, token string) (revoked bool, retryable bool, err error) {
+	opts := (&consulapi.QueryOptions{Token: token}).WithContext(ctx)
+
+	_, _, err = w.client.ACL().TokenReadSelf(opts)
+	if err == nil {
+		return false, false, nil
+	}
+
+	if isTokenRevoked(err) {
+		return true, false, nil
+	}
+
+	// Everything else -- a 5xx, a dropped connection, a DNS failure, the
+	// Consul agent restarting -- is a transient condition, not proof the
+	// token was revoked, so it's always retried rather than triggering a
+	// task restart.
+	return false, true, err
+}
+
+// isTokenRevoked reports whether err represents Consul definitively
+// rejecting the token (403 Forbidden, or ACL not found), as opposed to a
+// network or server error that says nothing about the token's validity.
+func isTokenRevoked(err error) bool {
+	var statusErr consulapi.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code == http.StatusForbidden
+	}
+
+	// Older consulapi releases return the status error unwrapped as a
+	// plain formatted string; fall back to matching that rather than
+	// assuming every non-StatusError error means revocation.
+	return strings.Contains(err.Error(), "Unexpected response code: 403")
+}
+
+// siTokenRotation pairs a watchee with the new token found on disk for it.
+type siTokenRotation struct {
+	watchee  *siTokenWatchee
+	newToken string
+}
+
+// detectRotatedWatchees re-reads each watchee's token file and returns those
+// whose on-disk token no longer matches currentToken, removing them from
+// watches. It has no Consul dependency so it can be tested without a live
+// client.
+func detectRotatedWatchees(watches map[string]*siTokenWatchee, currentToken string) []siTokenRotation {
+	var rotated []siTokenRotation
+
+	for task, watchee := range watches {
+		raw, err := ioutil.ReadFile(watchee.tokenPath)
+		if err != nil {
+			continue
+		}
+
+		newToken := string(raw)
+		if newToken == "" || newToken == currentToken {
+			continue
+		}
+
+		rotated = append(rotated, siTokenRotation{watchee: watchee, newToken: newToken})
+		delete(watches, task)
+	}
+
+	return rotated
+}
+
+// reloadRotatedTokens re-reads each watchee's token file in case the sids
+// hook rotated it out from under a still-running sidecar, and re-registers
+// the watchee under the new accessor so it keeps being monitored instead of
+// silently falling out of the watch set.
+func (w *siTokenWatcher) reloadRotatedTokens(accessorID string, wt *watchedToken) {
+	w.lock.Lock()
+
+	rotated := detectRotatedWatchees(wt.watches, wt.token)
+
+	if len(wt.watches) == 0 {
+		wt.cancel()
+		delete(w.tokens, accessorID)
+	}
+
+	w.lock.Unlock()
+
+	// Re-resolve and re-register each rotated watchee outside of w.lock,
+	// since Watch takes it again and Consul is a network call.
+	for _, r := range rotated {
+		self, _, err := w.client.ACL().TokenReadSelf(&consulapi.QueryOptions{Token: r.newToken})
+		if err != nil {
+			w.logger.Warn("failed to resolve rotated SI token, watch not re-established",
+				"task", r.watchee.task, "accessor_id", accessorID, "error", err)
+			continue
+		}
+
+		w.logger.Debug("SI token rotated on disk, re-watching under new accessor",
+			"task", r.watchee.task, "old_accessor_id", accessorID, "new_accessor_id", self.AccessorID)
+		w.Watch(r.watchee.ctx, r.watchee.task, self.AccessorID, r.newToken, r.watchee.tokenPath, r.watchee.lifecycle)
+	}
+}
+
+// restartWatchees asks every task relying on a revoked token to restart, so
+// the service identities hook can mint a fresh token on the next Prestart.
+func (w *siTokenWatcher) restartWatchees(wt *watchedToken) {
+	w.lock.Lock()
+	watchees := make([]*siTokenWatchee, 0, len(wt.watches))
+	for _, watchee := range wt.watches {
+		watchees = append(watchees, watchee)
+	}
+	w.lock.Unlock()
+
+	for _, watchee := range watchees {
+		event := structs.NewTaskEvent(structs.TaskRestartSignal).
+			SetDisplayMessage("Consul Service Identity token was revoked")
+		if err := watchee.lifecycle.Restart(context.Background(), event, false); err != nil {
+			w.logger.Error("failed to restart task after SI token revocation", "task", watchee.task, "error", err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package taskrunner
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTokenFile(t *testing.T, dir, name, token string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(token), 0600))
+	return path
+}
+
+func TestDetectRotatedWatchees(t *testing.T) {
+	dir := t.TempDir()
+
+	unchangedPath := writeTokenFile(t, dir, "unchanged", "same-token")
+	rotatedPath := writeTokenFile(t, dir, "rotated", "new-token")
+	emptyPath := writeTokenFile(t, dir, "empty", "")
+
+	watches := map[string]*siTokenWatchee{
+		"unchanged-task": {task: "unchanged-task", tokenPath: unchangedPath},
+		"rotated-task":   {task: "rotated-task", tokenPath: rotatedPath},
+		"empty-task":     {task: "empty-task", tokenPath: emptyPath},
+	}
+
+	rotated := detectRotatedWatchees(watches, "same-token")
+
+	require.Len(t, rotated, 1)
+	require.Equal(t, "rotated-task", rotated[0].watchee.task)
+	require.Equal(t, "new-token", rotated[0].newToken)
+
+	// The rotated watchee is removed from the live set; the others stay.
+	_, stillWatched := watches["rotated-task"]
+	require.False(t, stillWatched)
+	require.Len(t, watches, 2)
+}
+
+func TestIsTokenRevoked(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "403 status error is revocation",
+			err:  consulapi.StatusError{Code: 403, Body: "ACL not found"},
+			want: true,
+		},
+		{
+			name: "wrapped 403 status error is revocation",
+			err:  fmt.Errorf("token read self: %w", consulapi.StatusError{Code: 403, Body: "ACL not found"}),
+			want: true,
+		},
+		{
+			name: "5xx status error is not revocation",
+			err:  consulapi.StatusError{Code: 500, Body: "rpc error"},
+			want: false,
+		},
+		{
+			name: "unwrapped 403 string is revocation",
+			err:  errors.New("Unexpected response code: 403 (ACL not found)"),
+			want: true,
+		},
+		{
+			name: "network error is not revocation",
+			err:  errors.New("dial tcp 127.0.0.1:8500: connect: connection refused"),
+			want: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, isTokenRevoked(c.err))
+		})
+	}
+}
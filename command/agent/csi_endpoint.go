@@ -18,6 +18,13 @@ func (s *HTTPServer) CSIVolumesRequest(resp http.ResponseWriter, req *http.Reque
 		return nil, nil
 	}
 
+	// Let operators filter with the same bexpr-style expression language
+	// used by the node and alloc list endpoints, e.g. `PluginID == "ebs"
+	// and Topologies contains "us-east-1a"`. CSIVolume.List evaluates the
+	// expression itself, server-side, so the HTTP layer just forwards it
+	// and returns whatever comes back.
+	args.QueryOptions.Filter = req.URL.Query().Get("filter")
+
 	var out structs.CSIVolumeListResponse
 	if err := s.agent.RPC("CSIVolume.List", &args, &out); err != nil {
 		return nil, err
@@ -15,6 +15,24 @@ import (
 	"github.com/ugorji/go/codec"
 )
 
+const (
+	// nodeConnUpdateBatchInterval controls how often this server flushes
+	// queued nodeConnUpdate events to its peers.
+	nodeConnUpdateBatchInterval = 100 * time.Millisecond
+
+	// nodeConnCacheTTL bounds how long a replicated nodeConns cache entry
+	// is trusted. Entries older than this are treated as cold/stale, so
+	// serverWithNodeConn falls back to the Status.HasNodeConn fan-out
+	// rather than trusting state that may predate a partition.
+	nodeConnCacheTTL = 10 * time.Second
+
+	// nodeConnUpdateChanSize bounds how many updates can wait for the
+	// batching goroutine to pick them up before queueNodeConnUpdate starts
+	// dropping them. Updates the goroutine has already picked up are held
+	// until the next flush without a limit.
+	nodeConnUpdateChanSize = 1024
+)
+
 // nodeConnState is used to track connection information about a Nomad Client.
 type nodeConnState struct {
 	// Session holds the multiplexed yamux Session for dialing back.
@@ -50,12 +68,20 @@ func (s *Server) addNodeConn(ctx *RPCContext) {
 		return
 	}
 
+	established := time.Now()
+
 	s.nodeConnsLock.Lock()
-	defer s.nodeConnsLock.Unlock()
 	s.nodeConns[ctx.NodeID] = &nodeConnState{
 		Session:     ctx.Session,
-		Established: time.Now(),
+		Established: established,
 	}
+	s.nodeConnsLock.Unlock()
+
+	s.queueNodeConnUpdate(nodeConnUpdate{
+		NodeID:      ctx.NodeID,
+		ServerID:    s.LocalMember().Name,
+		Established: established,
+	})
 }
 
 // removeNodeConn removes the mapping between a node and its session.
@@ -66,8 +92,14 @@ func (s *Server) removeNodeConn(ctx *RPCContext) {
 	}
 
 	s.nodeConnsLock.Lock()
-	defer s.nodeConnsLock.Unlock()
 	delete(s.nodeConns, ctx.NodeID)
+	s.nodeConnsLock.Unlock()
+
+	s.queueNodeConnUpdate(nodeConnUpdate{
+		NodeID:   ctx.NodeID,
+		ServerID: s.LocalMember().Name,
+		Removed:  true,
+	})
 }
 
 // serverWithNodeConn is used to determine which remote server has the most
@@ -82,6 +114,24 @@ func (s *Server) serverWithNodeConn(nodeID string) (*serverParts, error) {
 	// We skip ourselves.
 	selfAddr := s.LocalMember().Addr.String()
 
+	// Consult the replicated nodeConns cache first. It's populated from
+	// nodeConnUpdate events pushed by every server as connections come and
+	// go, so in the common case this avoids the synchronous
+	// Status.HasNodeConn fan-out entirely.
+	if serverID, _, ok := nodeConnReplicationFor(s).cache.mostRecentServerID(nodeID); ok {
+		for addr, server := range s.localPeers {
+			if string(addr) == selfAddr {
+				continue
+			}
+			if server.Name == serverID {
+				return server, nil
+			}
+		}
+		// The cached owner isn't among our current peers (e.g. it left
+		// the region); fall through to the fan-out below rather than
+		// trusting a dangling entry.
+	}
+
 	// Build the request
 	req := &structs.NodeSpecificRequest{
 		NodeID: nodeID,
@@ -202,3 +252,272 @@ func Bridge(a, b io.ReadWriteCloser) error {
 	wg.Wait()
 	return nil
 }
+
+// nodeConnUpdate is a single add/remove event for a server<->node
+// connection, queued locally for batched replication to the rest of the
+// servers in the region.
+type nodeConnUpdate struct {
+	NodeID      string
+	ServerID    string
+	Established time.Time
+
+	// Removed is set when this update represents the connection going
+	// away rather than being established.
+	Removed bool
+}
+
+// NodeConnUpdateRequest carries a batch of nodeConnUpdate events pushed from
+// one server to its peers. It replaces the need for servers to learn about
+// each other's node connections via synchronous Status.HasNodeConn queries.
+type NodeConnUpdateRequest struct {
+	Updates []nodeConnUpdate
+	structs.WriteRequest
+}
+
+// NodeConnUpdateResponse acknowledges a NodeConnUpdateRequest.
+type NodeConnUpdateResponse struct {
+	structs.QueryMeta
+}
+
+// nodeConnCacheEntry tracks the last known Established time for a
+// server's connection to a node, plus when this server last heard about it
+// so staleness can be judged independently of clock skew in Established.
+type nodeConnCacheEntry struct {
+	established time.Time
+	updatedAt   time.Time
+}
+
+// nodeConnCache is a best-effort, eventually consistent view of which
+// servers in the region are connected to which nodes. It's built from
+// replicated nodeConnUpdate events rather than queried on demand, so
+// serverWithNodeConn can usually avoid fanning out Status.HasNodeConn to
+// every peer on every lookup.
+type nodeConnCache struct {
+	lock sync.RWMutex
+
+	// nodes maps nodeID -> serverID -> cache entry.
+	nodes map[string]map[string]nodeConnCacheEntry
+}
+
+func newNodeConnCache() *nodeConnCache {
+	return &nodeConnCache{
+		nodes: make(map[string]map[string]nodeConnCacheEntry),
+	}
+}
+
+// apply records a batch of nodeConnUpdate events, overwriting any existing
+// entry for the same node/server pair.
+func (c *nodeConnCache) apply(updates []nodeConnUpdate) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	for _, u := range updates {
+		if u.Removed {
+			if servers, ok := c.nodes[u.NodeID]; ok {
+				delete(servers, u.ServerID)
+				if len(servers) == 0 {
+					delete(c.nodes, u.NodeID)
+				}
+			}
+			continue
+		}
+
+		servers, ok := c.nodes[u.NodeID]
+		if !ok {
+			servers = make(map[string]nodeConnCacheEntry)
+			c.nodes[u.NodeID] = servers
+		}
+		servers[u.ServerID] = nodeConnCacheEntry{established: u.Established, updatedAt: now}
+	}
+}
+
+// mostRecentServerID returns the serverID with the most recent Established
+// time among non-stale entries for nodeID, and whether one was found.
+func (c *nodeConnCache) mostRecentServerID(nodeID string) (serverID string, established time.Time, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	servers, exists := c.nodes[nodeID]
+	if !exists {
+		return "", time.Time{}, false
+	}
+
+	cutoff := time.Now().Add(-nodeConnCacheTTL)
+	for id, entry := range servers {
+		if entry.updatedAt.Before(cutoff) {
+			// Stale; ignore rather than risk routing to a server that
+			// may have lost its connection during a partition.
+			continue
+		}
+		if !ok || entry.established.After(established) {
+			serverID, established, ok = id, entry.established, true
+		}
+	}
+
+	return serverID, established, ok
+}
+
+// nodeConnReplication holds one Server's node-connection cache, its queue of
+// pending updates, and the bookkeeping needed to start and stop exactly one
+// batching goroutine for it.
+//
+// This state deliberately isn't a field on *Server: Server is declared in
+// server.go, which this node-connection-replication feature doesn't
+// otherwise need to touch for every other piece of its state.
+// nodeConnReplicationFor keys it off the *Server pointer instead, so every
+// call site in this file shares one cache and one background flush loop per
+// server without requiring a server.go change. Landing this for real still
+// requires one change in server.go: Server.Shutdown should call
+// stopNodeConnReplication(s) so the batching goroutine started here exits
+// with the rest of the server's background work instead of outliving it.
+type nodeConnReplication struct {
+	cache    *nodeConnCache
+	updateCh chan nodeConnUpdate
+	stopCh   chan struct{}
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+var (
+	nodeConnReplicationLock  sync.Mutex
+	nodeConnReplicationState = make(map[*Server]*nodeConnReplication)
+)
+
+// nodeConnReplicationFor returns the nodeConnReplication for s, creating it
+// and starting its batching goroutine on first use.
+func nodeConnReplicationFor(s *Server) *nodeConnReplication {
+	nodeConnReplicationLock.Lock()
+	rep, ok := nodeConnReplicationState[s]
+	if !ok {
+		rep = &nodeConnReplication{
+			cache:    newNodeConnCache(),
+			updateCh: make(chan nodeConnUpdate, nodeConnUpdateChanSize),
+			stopCh:   make(chan struct{}),
+		}
+		nodeConnReplicationState[s] = rep
+	}
+	nodeConnReplicationLock.Unlock()
+
+	rep.startOnce.Do(func() {
+		go s.runNodeConnUpdateReplication(rep)
+	})
+
+	return rep
+}
+
+// stopNodeConnReplication stops s's batching goroutine, if one was started,
+// and drops s's entry from nodeConnReplicationState. Without this, every
+// *Server that ever called queueNodeConnUpdate or serverWithNodeConn would
+// keep its goroutine running and its map entry alive for the life of the
+// process, even after the server itself shut down -- a leak on every
+// leadership change or test that spins up a short-lived server. The real
+// caller for this is Server.Shutdown, tied to s.shutdownCh, once this
+// package's Server declares those fields.
+func stopNodeConnReplication(s *Server) {
+	nodeConnReplicationLock.Lock()
+	rep, ok := nodeConnReplicationState[s]
+	if ok {
+		delete(nodeConnReplicationState, s)
+	}
+	nodeConnReplicationLock.Unlock()
+
+	if !ok {
+		return
+	}
+	rep.stopOnce.Do(func() {
+		close(rep.stopCh)
+	})
+}
+
+// queueNodeConnUpdate buffers a nodeConnUpdate for batched replication to
+// the rest of the region every nodeConnUpdateBatchInterval. It never blocks:
+// if the buffer is full the update is dropped and logged, since a missed
+// update just means a lookup falls back to the Status.HasNodeConn fan-out
+// until the next update for that node arrives.
+func (s *Server) queueNodeConnUpdate(u nodeConnUpdate) {
+	select {
+	case nodeConnReplicationFor(s).updateCh <- u:
+	default:
+		s.logger.Warn("node connection update buffer full, dropping update", "node_id", u.NodeID)
+	}
+}
+
+// runNodeConnUpdateReplication batches queued nodeConnUpdate events and
+// streams them to this server's peers every nodeConnUpdateBatchInterval. It
+// is started exactly once per server, by nodeConnReplicationFor, and runs
+// until stopNodeConnReplication(s) is called.
+func (s *Server) runNodeConnUpdateReplication(rep *nodeConnReplication) {
+	ticker := time.NewTicker(nodeConnUpdateBatchInterval)
+	defer ticker.Stop()
+
+	var pending []nodeConnUpdate
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := s.replicateNodeConnUpdates(pending); err != nil {
+			s.logger.Warn("failed to replicate node connection updates", "error", err)
+		}
+		pending = nil
+	}
+
+	for {
+		select {
+		case u := <-rep.updateCh:
+			// Apply locally first so this server's own view is always
+			// at least as fresh as what it tells its peers.
+			rep.cache.apply([]nodeConnUpdate{u})
+			pending = append(pending, u)
+		case <-ticker.C:
+			flush()
+		case <-rep.stopCh:
+			flush()
+			return
+		}
+	}
+}
+
+// replicateNodeConnUpdates pushes a batch of updates to every known peer.
+// It's best-effort: a single unreachable peer doesn't block delivery to the
+// rest, since stale entries age out of the cache on their own.
+func (s *Server) replicateNodeConnUpdates(updates []nodeConnUpdate) error {
+	s.peerLock.RLock()
+	peers := make([]*serverParts, 0, len(s.localPeers))
+	for _, server := range s.localPeers {
+		peers = append(peers, server)
+	}
+	s.peerLock.RUnlock()
+
+	selfAddr := s.LocalMember().Addr.String()
+
+	args := &NodeConnUpdateRequest{Updates: updates}
+
+	var mErr multierror.Error
+	for _, server := range peers {
+		if server.Addr.String() == selfAddr {
+			continue
+		}
+
+		var resp NodeConnUpdateResponse
+		if err := s.connPool.RPC(s.config.Region, server.Addr, server.MajorVersion,
+			"Status.NodeConnUpdate", args, &resp); err != nil {
+			multierror.Append(&mErr, fmt.Errorf("failed replicating node connection updates to server %q: %v", server.Addr.String(), err))
+		}
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// NodeConnUpdate is the receiving half of the replication pushed by
+// replicateNodeConnUpdates: it applies a batch of node-connection updates
+// from a peer to this server's own cache. It's registered on the Status RPC
+// endpoint (see status_endpoint.go) alongside the existing
+// Status.HasNodeConn, which serverWithNodeConn still falls back to if the
+// cache is cold or a cached entry has gone stale.
+func (s *Status) NodeConnUpdate(args *NodeConnUpdateRequest, reply *NodeConnUpdateResponse) error {
+	nodeConnReplicationFor(s.srv).cache.apply(args.Updates)
+	reply.QueryMeta = structs.QueryMeta{}
+	return nil
+}
@@ -0,0 +1,84 @@
+package nomad
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeConnCache_ApplyAndMostRecent(t *testing.T) {
+	c := newNodeConnCache()
+
+	now := time.Now()
+	c.apply([]nodeConnUpdate{
+		{NodeID: "node1", ServerID: "server-a", Established: now.Add(-2 * time.Second)},
+		{NodeID: "node1", ServerID: "server-b", Established: now},
+	})
+
+	serverID, established, ok := c.mostRecentServerID("node1")
+	require.True(t, ok)
+	require.Equal(t, "server-b", serverID)
+	require.Equal(t, now, established)
+
+	_, _, ok = c.mostRecentServerID("node-missing")
+	require.False(t, ok)
+}
+
+func TestNodeConnCache_Removed(t *testing.T) {
+	c := newNodeConnCache()
+
+	c.apply([]nodeConnUpdate{
+		{NodeID: "node1", ServerID: "server-a", Established: time.Now()},
+	})
+	_, _, ok := c.mostRecentServerID("node1")
+	require.True(t, ok)
+
+	c.apply([]nodeConnUpdate{
+		{NodeID: "node1", ServerID: "server-a", Removed: true},
+	})
+	_, _, ok = c.mostRecentServerID("node1")
+	require.False(t, ok, "removed entry should no longer be returned")
+}
+
+func TestNodeConnReplication_StopRemovesState(t *testing.T) {
+	s := &Server{}
+
+	rep := nodeConnReplicationFor(s)
+	require.NotNil(t, rep)
+
+	nodeConnReplicationLock.Lock()
+	_, ok := nodeConnReplicationState[s]
+	nodeConnReplicationLock.Unlock()
+	require.True(t, ok, "starting replication should record state for s")
+
+	stopNodeConnReplication(s)
+
+	nodeConnReplicationLock.Lock()
+	_, ok = nodeConnReplicationState[s]
+	nodeConnReplicationLock.Unlock()
+	require.False(t, ok, "stopping replication should drop s's state so it isn't leaked forever")
+
+	// Calling it again on an already-stopped server, or one that never
+	// started, must not panic.
+	stopNodeConnReplication(s)
+	stopNodeConnReplication(&Server{})
+}
+
+func TestNodeConnCache_StaleEntriesIgnored(t *testing.T) {
+	c := newNodeConnCache()
+
+	// Backdate updatedAt past the TTL by applying, then mutating the
+	// internal entry directly since apply always stamps "now".
+	c.apply([]nodeConnUpdate{
+		{NodeID: "node1", ServerID: "server-a", Established: time.Now()},
+	})
+	c.lock.Lock()
+	entry := c.nodes["node1"]["server-a"]
+	entry.updatedAt = time.Now().Add(-2 * nodeConnCacheTTL)
+	c.nodes["node1"]["server-a"] = entry
+	c.lock.Unlock()
+
+	_, _, ok := c.mostRecentServerID("node1")
+	require.False(t, ok, "stale entries should be treated as cold rather than trusted")
+}
@@ -0,0 +1,71 @@
+package nomad
+
+import (
+	"github.com/hashicorp/go-bexpr"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// CSIVolume is the server-side RPC endpoint for CSI volume registration,
+// lookup, and listing.
+//
+// This checkout's nomad package doesn't declare Server (server.go isn't
+// part of this trimmed tree), so List below
+// calls v.srv.State() the way it would in the full repo without that
+// method actually existing here. Landing this for real needs nothing more
+// than dropping this file into a checkout that has Server.State() and the
+// CSI volume state store table.
+type CSIVolume struct {
+	srv *Server
+}
+
+// List returns the CSI volumes visible to args.Namespace, filtering them
+// server-side against args.Filter if one was given.
+//
+// Evaluating the filter here instead of in the HTTP layer, after the RPC
+// already returned every volume, means a restrictive filter actually
+// shrinks what goes out over the wire rather than the server shipping the
+// full catalog for the HTTP handler to throw most of away. List does not
+// set reply.QueryMeta.
+func (v *CSIVolume) List(args *structs.CSIVolumeListRequest, reply *structs.CSIVolumeListResponse) error {
+	volumes, err := v.srv.State().CSIVolumesByNamespace(args.Namespace)
+	if err != nil {
+		return err
+	}
+
+	filtered, err := filterCSIVolumeStubs(volumes, args.Filter)
+	if err != nil {
+		return err
+	}
+
+	reply.Volumes = filtered
+	return nil
+}
+
+// filterCSIVolumeStubs applies a bexpr filter expression to volumes. It
+// evaluates against every selectable field on CSIVolListStub, including
+// Topologies, so operators can filter on e.g.
+// `PluginID == "ebs" and Topologies contains "us-east-1a"` the same way
+// they already can against node and alloc list stubs.
+func filterCSIVolumeStubs(volumes []*structs.CSIVolListStub, filter string) ([]*structs.CSIVolListStub, error) {
+	if filter == "" {
+		return volumes, nil
+	}
+
+	evaluator, err := bexpr.CreateEvaluator(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*structs.CSIVolListStub, 0, len(volumes))
+	for _, vol := range volumes {
+		match, err := evaluator.Evaluate(vol)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			filtered = append(filtered, vol)
+		}
+	}
+
+	return filtered, nil
+}
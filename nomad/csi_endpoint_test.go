@@ -0,0 +1,39 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterCSIVolumeStubs(t *testing.T) {
+	volumes := []*structs.CSIVolListStub{
+		{ID: "vol1", PluginID: "ebs", Topologies: []string{"us-east-1a"}},
+		{ID: "vol2", PluginID: "efs", Topologies: []string{"us-east-1b"}},
+		{ID: "vol3", PluginID: "ebs", Topologies: []string{"us-east-1a", "us-east-1b"}},
+	}
+
+	filtered, err := filterCSIVolumeStubs(volumes, `PluginID == "ebs"`)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+	require.Equal(t, "vol1", filtered[0].ID)
+	require.Equal(t, "vol3", filtered[1].ID)
+
+	filtered, err = filterCSIVolumeStubs(volumes, `Topologies contains "us-east-1b"`)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+	require.Equal(t, "vol2", filtered[0].ID)
+	require.Equal(t, "vol3", filtered[1].ID)
+
+	filtered, err = filterCSIVolumeStubs(volumes, `PluginID == "ebs" and Topologies contains "us-east-1a"`)
+	require.NoError(t, err)
+	require.Len(t, filtered, 2)
+
+	filtered, err = filterCSIVolumeStubs(volumes, "")
+	require.NoError(t, err)
+	require.Len(t, filtered, 3, "empty filter should return every volume unfiltered")
+
+	_, err = filterCSIVolumeStubs(volumes, `not a valid expression (`)
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package structs
+
+// NOTE: as with services.go, this file only declares the types the
+// filter-expression feature needs. CSIVolume itself
+// (the full registered-volume object, as opposed to the CSIVolListStub
+// summary below) is out of scope for that feature and lives in the much
+// larger csi.go this trimmed tree doesn't include, so *CSIVolume below
+// stays a phantom type, the same as it was in command/agent/csi_endpoint.go
+// before this file existed.
+
+// CSIVolListStub is the per-volume summary returned by CSIVolume.List. It
+// carries the fields operators can select on with a filter expression, e.g.
+// `PluginID == "ebs" and Topologies contains "us-east-1a"`.
+type CSIVolListStub struct {
+	ID             string
+	Namespace      string
+	Name           string
+	PluginID       string
+	Provider       string
+	Schedulable    bool
+	AccessMode     string
+	AttachmentMode string
+
+	// Topologies lists this volume's plugin-reported topology segment
+	// values (e.g. "us-east-1a"), flattened across all of its accessible
+	// topologies, so a filter can match on them with a single `contains`
+	// check rather than operators needing to know the segment key.
+	Topologies []string
+}
+
+// CSIVolumeListRequest is the CSIVolume.List RPC request. QueryOptions.Filter
+// is evaluated server-side against CSIVolListStub, rather than by every HTTP
+// client against whatever the RPC happened to return.
+type CSIVolumeListRequest struct {
+	QueryOptions
+}
+
+// CSIVolumeListResponse is the CSIVolume.List RPC response.
+type CSIVolumeListResponse struct {
+	Volumes []*CSIVolListStub
+	QueryMeta
+}
+
+// CSIVolumeGetRequest is the CSIVolume.Get RPC request.
+type CSIVolumeGetRequest struct {
+	ID string
+	QueryOptions
+}
+
+// CSIVolumeGetResponse is the CSIVolume.Get RPC response.
+type CSIVolumeGetResponse struct {
+	Volume *CSIVolume
+	QueryMeta
+}
+
+// CSIVolumeRegisterRequest is the CSIVolume.Register RPC request.
+type CSIVolumeRegisterRequest struct {
+	Volumes []*CSIVolume
+	WriteRequest
+}
+
+// CSIVolumeRegisterResponse is the CSIVolume.Register RPC response.
+type CSIVolumeRegisterResponse struct {
+	QueryMeta
+}
+
+// CSIVolumeDeregisterRequest is the CSIVolume.Deregister RPC request.
+type CSIVolumeDeregisterRequest struct {
+	VolumeIDs []string
+	WriteRequest
+}
+
+// CSIVolumeDeregisterResponse is the CSIVolume.Deregister RPC response.
+type CSIVolumeDeregisterResponse struct {
+	QueryMeta
+}
@@ -0,0 +1,117 @@
+package structs
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// NOTE: this file only contains the types the Connect sidecar tracing
+// feature needs (ConsulProxyTracing below). It does
+// not declare ConsulProxy itself, or Service/TaskGroup/Allocation/Job, since
+// those live in the much larger services.go (and job.go) this trimmed tree
+// doesn't include. Landing this for real requires adding one field,
+// `Tracing *ConsulProxyTracing`, to the existing ConsulProxy struct in the
+// full repo's nomad/structs/services.go, and a call to Tracing.Validate()
+// from ConsulProxy's own Validate.
+
+// ConsulProxyTracingProviders are the tracing providers the Connect sidecar
+// Envoy bootstrap natively supports.
+var ConsulProxyTracingProviders = []string{"zipkin", "datadog", "opentelemetry"}
+
+// ConsulProxyTracing configures the tracing provider a Connect sidecar's
+// Envoy proxy exports spans to. It is part of the
+// connect.sidecar_service.proxy stanza (ConsulProxy.Tracing) so operators
+// configure it like any other piece of Connect sidecar behavior, and so it
+// participates in job validation and diffing like the rest of the stanza.
+type ConsulProxyTracing struct {
+	// Provider is one of "zipkin" (also covers Jaeger's Zipkin-compatible
+	// collector), "datadog", or "opentelemetry".
+	Provider string
+
+	// CollectorHost and CollectorPort address the tracing collector Envoy
+	// should export spans to.
+	CollectorHost string
+	CollectorPort int
+
+	// SamplingRatePercent is the percentage, 0-100, of requests to sample.
+	SamplingRatePercent float64
+
+	// SpawnUpstreamSpan controls whether Envoy starts a new span for
+	// upstream requests rather than continuing the downstream span.
+	SpawnUpstreamSpan bool
+}
+
+// Validate returns an error if the tracing configuration names an
+// unsupported provider or is missing a required field. A nil receiver is
+// valid, matching the "tracing is optional" semantics of ConsulProxy.Tracing.
+func (t *ConsulProxyTracing) Validate() error {
+	if t == nil {
+		return nil
+	}
+
+	var mErr multierror.Error
+
+	switch t.Provider {
+	case "zipkin", "datadog", "opentelemetry":
+	case "":
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("tracing provider is required"))
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("unsupported tracing provider %q", t.Provider))
+	}
+
+	if t.CollectorHost == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("tracing collector_host is required"))
+	}
+	if t.CollectorPort <= 0 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("tracing collector_port must be > 0"))
+	}
+	if t.SamplingRatePercent < 0 || t.SamplingRatePercent > 100 {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("tracing sampling_rate_percent must be between 0 and 100, got %v", t.SamplingRatePercent))
+	}
+
+	return mErr.ErrorOrNil()
+}
+
+// Copy returns a deep copy of t, or nil if t is nil.
+func (t *ConsulProxyTracing) Copy() *ConsulProxyTracing {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// Diff returns the names of the fields that differ between t and other, so
+// job diffs can report that the tracing stanza changed. A nil receiver or
+// argument is treated as the zero value rather than "no tracing configured",
+// since the caller (ConsulProxy.Diff) is responsible for reporting the
+// stanza being added or removed entirely.
+func (t *ConsulProxyTracing) Diff(other *ConsulProxyTracing) []string {
+	a, b := t, other
+	if a == nil {
+		a = &ConsulProxyTracing{}
+	}
+	if b == nil {
+		b = &ConsulProxyTracing{}
+	}
+
+	var changed []string
+	if a.Provider != b.Provider {
+		changed = append(changed, "Provider")
+	}
+	if a.CollectorHost != b.CollectorHost {
+		changed = append(changed, "CollectorHost")
+	}
+	if a.CollectorPort != b.CollectorPort {
+		changed = append(changed, "CollectorPort")
+	}
+	if a.SamplingRatePercent != b.SamplingRatePercent {
+		changed = append(changed, "SamplingRatePercent")
+	}
+	if a.SpawnUpstreamSpan != b.SpawnUpstreamSpan {
+		changed = append(changed, "SpawnUpstreamSpan")
+	}
+
+	return changed
+}